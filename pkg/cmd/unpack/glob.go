@@ -0,0 +1,95 @@
+// Copyright 2024 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package unpack
+
+import (
+	"path"
+	"strings"
+)
+
+// matchGlob reports whether entryPath (a forward-slash separated tar entry path, relative to a
+// layer's root) matches pattern. Patterns follow doublestar-style globbing: "*" matches any run
+// of characters within a single path segment, and "**" matches any number of path segments
+// (including none), so a pattern like "datasets/train/**" matches every entry beneath
+// datasets/train.
+func matchGlob(pattern, entryPath string) bool {
+	return matchGlobParts(strings.Split(pattern, "/"), strings.Split(entryPath, "/"))
+}
+
+// matchAnyGlob reports whether entryPath matches at least one of patterns. An empty patterns
+// slice matches nothing.
+func matchAnyGlob(patterns []string, entryPath string) bool {
+	for _, pattern := range patterns {
+		if matchGlob(pattern, entryPath) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchGlobParts(pattern, entry []string) bool {
+	if len(pattern) == 0 {
+		return len(entry) == 0
+	}
+	if pattern[0] == "**" {
+		if matchGlobParts(pattern[1:], entry) {
+			return true
+		}
+		if len(entry) == 0 {
+			return false
+		}
+		return matchGlobParts(pattern, entry[1:])
+	}
+	if len(entry) == 0 {
+		return false
+	}
+	if ok, err := path.Match(pattern[0], entry[0]); err != nil || !ok {
+		return false
+	}
+	return matchGlobParts(pattern[1:], entry[1:])
+}
+
+// dirMayContainMatch reports whether dirPath could be an ancestor of some path matched by one
+// of includePatterns, i.e. whether it's still necessary to create dirPath in order to host
+// included entries further down the tree.
+func dirMayContainMatch(includePatterns []string, dirPath string) bool {
+	dirParts := strings.Split(dirPath, "/")
+	for _, pattern := range includePatterns {
+		if dirCouldHostMatch(strings.Split(pattern, "/"), dirParts) {
+			return true
+		}
+	}
+	return false
+}
+
+func dirCouldHostMatch(pattern, dir []string) bool {
+	if len(pattern) == 0 {
+		return true
+	}
+	if pattern[0] == "**" {
+		return true
+	}
+	if len(dir) == 0 {
+		// The directory ends before the pattern does, so it's a strict ancestor of
+		// anything the remaining pattern segments could match.
+		return true
+	}
+	if ok, err := path.Match(pattern[0], dir[0]); err != nil || !ok {
+		return false
+	}
+	return dirCouldHostMatch(pattern[1:], dir[1:])
+}
@@ -0,0 +1,56 @@
+// Copyright 2024 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package unpack
+
+import "kitops/pkg/lib/repo"
+
+// unpackConf selects which parts of a ModelKit should be unpacked.
+type unpackConf struct {
+	unpackConfig   bool
+	unpackModels   bool
+	unpackCode     bool
+	unpackDatasets bool
+}
+
+// unpackOptions holds the configuration for a single unpack operation.
+type unpackOptions struct {
+	unpackDir  string
+	overwrite  bool
+	unpackConf unpackConf
+
+	// variantSelector chooses which child manifest to unpack when the resolved reference
+	// points at a multi-variant ModelKit (an OCI image index) rather than a single manifest.
+	// It is ignored when the reference already resolves to a single manifest.
+	variantSelector *repo.VariantSelector
+
+	// referrerArtifactTypes, when non-empty, causes unpackModel to also fetch any OCI 1.1
+	// referrers of the resolved ModelKit manifest matching one of these artifact types (e.g.
+	// signatures, SBOMs, eval reports) and materialize them under unpackDir.
+	referrerArtifactTypes []string
+
+	// concurrency is the maximum number of layers fetched and extracted at the same time.
+	// A value <= 1 unpacks layers one at a time, preserving the original sequential behavior.
+	concurrency int
+
+	// includePatterns and excludePatterns are doublestar-style globs (e.g. "datasets/train/**"),
+	// evaluated against each tar entry's path relative to its layer root, that restrict which
+	// entries of a layer are actually written to disk. excludePatterns is applied first; when
+	// includePatterns is non-empty, only entries matching one of its patterns (and the parent
+	// directories needed to hold them) are extracted.
+	includePatterns []string
+	excludePatterns []string
+}
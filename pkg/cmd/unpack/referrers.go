@@ -0,0 +1,96 @@
+// Copyright 2024 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package unpack
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"kitops/pkg/lib/assetsclient"
+	"kitops/pkg/lib/repo"
+	"kitops/pkg/output"
+	"os"
+	"path/filepath"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// unpackReferrers fetches referrers of subjectDesc matching options.referrerArtifactTypes and
+// writes each one's layers into .kitops/attestations/<subject digest>/<referrer digest>/ under
+// options.unpackDir, preserving the referrer's artifactType in a sibling ".artifactType" file
+// so downstream tooling (e.g. signature or SBOM verifiers) can identify what it's looking at
+// without re-fetching the manifest.
+func unpackReferrers(ctx context.Context, store assetsclient.AssetsClient, subjectDesc ocispec.Descriptor, options *unpackOptions) error {
+	if len(options.referrerArtifactTypes) == 0 {
+		return nil
+	}
+
+	referrers, err := repo.ListReferrers(ctx, store, subjectDesc, options.referrerArtifactTypes)
+	if err != nil {
+		return fmt.Errorf("failed to list referrers: %w", err)
+	}
+	if len(referrers) == 0 {
+		output.Debugf("No matching referrers found for %s", subjectDesc.Digest)
+		return nil
+	}
+
+	subjectDir := filepath.Join(options.unpackDir, ".kitops", "attestations", subjectDesc.Digest.Encoded())
+	for _, referrerDesc := range referrers {
+		referrerDir := filepath.Join(subjectDir, referrerDesc.Digest.Encoded())
+		if err := os.MkdirAll(referrerDir, 0755); err != nil {
+			return fmt.Errorf("failed to create directory %s: %w", referrerDir, err)
+		}
+		output.Infof("Unpacking referrer %s (%s) to %s", referrerDesc.Digest, referrerDesc.ArtifactType, referrerDir)
+
+		// referrerDesc is a referrer manifest, not a ModelKit manifest, so its Config never
+		// has a registered Kitfile media type -- GetRawManifest skips that check.
+		manifest, err := repo.GetRawManifest(ctx, store, referrerDesc)
+		if err != nil {
+			return fmt.Errorf("failed to read referrer manifest %s: %w", referrerDesc.Digest, err)
+		}
+		for _, layerDesc := range manifest.Layers {
+			if err := writeReferrerLayer(ctx, store, layerDesc, referrerDir); err != nil {
+				return err
+			}
+		}
+		artifactTypeFile := filepath.Join(referrerDir, ".artifactType")
+		if err := os.WriteFile(artifactTypeFile, []byte(referrerDesc.ArtifactType), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", artifactTypeFile, err)
+		}
+	}
+	return nil
+}
+
+func writeReferrerLayer(ctx context.Context, store assetsclient.AssetsClient, desc ocispec.Descriptor, dir string) error {
+	rc, err := store.Fetch(ctx, desc)
+	if err != nil {
+		return fmt.Errorf("failed to fetch referrer layer %s: %w", desc.Digest, err)
+	}
+	defer rc.Close()
+
+	outPath := filepath.Join(dir, desc.Digest.Encoded())
+	file, err := os.OpenFile(outPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %w", outPath, err)
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, rc); err != nil {
+		return fmt.Errorf("failed to write referrer layer %s: %w", outPath, err)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2024 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package unpack
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	layerStateComplete = "complete"
+	layerStatePartial  = "partial"
+)
+
+// layerState records the progress of unpacking a single layer, keyed by its digest, so that an
+// interrupted unpack can resume without re-fetching layers that already finished.
+type layerState struct {
+	Status       string `json:"status"`
+	BytesWritten int64  `json:"bytesWritten"`
+}
+
+// unpackState is the resumable state of an in-progress unpack, persisted as JSON at
+// <unpackDir>/.kitops/unpack-state.json. It is safe for concurrent use.
+type unpackState struct {
+	mu     sync.Mutex
+	path   string
+	Layers map[string]*layerState `json:"layers"`
+}
+
+// unpackStatePath returns the path of the resume state file for a given unpack directory.
+func unpackStatePath(unpackDir string) string {
+	return filepath.Join(unpackDir, ".kitops", "unpack-state.json")
+}
+
+// loadUnpackState reads the resume state file at path, if it exists, returning an empty state
+// otherwise. A missing or unparseable state file is not an error: unpacking simply starts over.
+func loadUnpackState(path string) *unpackState {
+	state := &unpackState{path: path, Layers: map[string]*layerState{}}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return state
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return &unpackState{path: path, Layers: map[string]*layerState{}}
+	}
+	return state
+}
+
+// get returns a copy of the recorded state for digest, if any.
+func (s *unpackState) get(digest string) (layerState, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.Layers[digest]
+	if !ok {
+		return layerState{}, false
+	}
+	return *st, true
+}
+
+// set records the state for digest and persists the state file to disk.
+func (s *unpackState) set(digest string, st layerState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Layers[digest] = &st
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to marshal unpack state: %w", err)
+	}
+	if err := os.WriteFile(s.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write unpack state: %w", err)
+	}
+	return nil
+}
@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"io"
 	"kitops/pkg/artifact"
+	"kitops/pkg/lib/assetsclient"
 	"kitops/pkg/lib/constants"
 	"kitops/pkg/lib/filesystem"
 	"kitops/pkg/lib/repo"
@@ -31,19 +32,62 @@ import (
 	"path/filepath"
 
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"golang.org/x/sync/errgroup"
 	"oras.land/oras-go/v2"
-	"oras.land/oras-go/v2/content"
 	"oras.land/oras-go/v2/registry"
 )
 
-// unpackModel fetches and unpacks a *registry.Reference from an oras.Target. It returns an error if
+// layerTask describes a single manifest layer to unpack: its descriptor, the directory it
+// should be extracted into, and a human-readable label used for progress output.
+type layerTask struct {
+	desc    ocispec.Descriptor
+	dir     string
+	relPath string
+	label   string
+}
+
+// Unpack resolves refString to an AssetsClient backend and unpacks the ModelKit it addresses.
+// refString may carry any of the URI schemes repo.ParseReference recognizes (oci://, http://,
+// https://, s3://, file://); a reference with no recognized scheme is treated as oci://, kitops'
+// original registry/repository:tag addressing. target is only used for the oci scheme, where it
+// is the already-resolved local OCI layout store or registry client for the reference; it is
+// ignored for every other scheme. See unpackModel for the rest of the unpacking behavior.
+func Unpack(ctx context.Context, refString string, target oras.Target, creds assetsclient.Credentials, options *unpackOptions) error {
+	scheme, ref, _, err := repo.ParseReference(refString)
+	if err != nil {
+		return fmt.Errorf("failed to parse reference %s: %w", refString, err)
+	}
+	store, err := assetsclient.NewClientForReference(scheme, ref, target, creds)
+	if err != nil {
+		return fmt.Errorf("failed to construct client for reference %s: %w", refString, err)
+	}
+	return unpackModel(ctx, store, ref, options)
+}
+
+// unpackModel fetches and unpacks a *registry.Reference from an assetsclient.AssetsClient. It returns an error if
 // unpacking fails, or if any path specified in the modelkit is not a subdirectory of the current
 // unpack target directory.
-func unpackModel(ctx context.Context, store oras.Target, ref *registry.Reference, options *unpackOptions) error {
+//
+// If the reference resolves to an OCI image index rather than a single ModelKit manifest (i.e. the
+// ModelKit has multiple variants such as different quantizations or hardware targets),
+// options.variantSelector is used to pick the single child manifest to unpack.
+//
+// If options.referrerArtifactTypes is set, referrers of the resolved manifest (signatures,
+// SBOMs, eval reports, etc.) are also fetched and materialized under options.unpackDir.
+//
+// Layers are fetched and extracted concurrently (bounded by options.concurrency), each verified
+// against its expected digest as it streams in, and resumable across runs via a small state file
+// under options.unpackDir/.kitops/unpack-state.json. Within each layer, options.includePatterns
+// and options.excludePatterns can restrict extraction to a subset of the archive's entries.
+func unpackModel(ctx context.Context, store assetsclient.AssetsClient, ref *registry.Reference, options *unpackOptions) error {
 	manifestDesc, err := store.Resolve(ctx, ref.Reference)
 	if err != nil {
 		return fmt.Errorf("failed to resolve local reference: %w", err)
 	}
+	manifestDesc, err = repo.ResolveVariant(ctx, store, manifestDesc, options.variantSelector)
+	if err != nil {
+		return fmt.Errorf("failed to resolve modelkit variant: %w", err)
+	}
 	manifest, config, err := repo.GetManifestAndConfig(ctx, store, manifestDesc)
 	if err != nil {
 		return fmt.Errorf("failed to read local model: %s", err)
@@ -58,8 +102,9 @@ func unpackModel(ctx context.Context, store oras.Target, ref *registry.Reference
 	// Since there might be multiple models, etc. we need to synchronously iterate
 	// through the config's relevant field to get the correct path for unpacking
 	var codeIdx, datasetIdx int
+	var tasks []layerTask
 	for _, layerDesc := range manifest.Layers {
-		var layerDir, relPath string
+		var layerDir, relPath, label string
 		switch layerDesc.MediaType {
 		case constants.ModelLayerMediaType:
 			if !options.unpackConf.unpackModels {
@@ -69,7 +114,7 @@ func unpackModel(ctx context.Context, store oras.Target, ref *registry.Reference
 			if err != nil {
 				return fmt.Errorf("Error resolving model path: %w", err)
 			}
-			output.Infof("Unpacking model to %s", relPath)
+			label = fmt.Sprintf("model to %s", relPath)
 
 		case constants.CodeLayerMediaType:
 			if !options.unpackConf.unpackCode {
@@ -80,7 +125,7 @@ func unpackModel(ctx context.Context, store oras.Target, ref *registry.Reference
 			if err != nil {
 				return fmt.Errorf("Error resolving code path: %w", err)
 			}
-			output.Infof("Unpacking code to %s", relPath)
+			label = fmt.Sprintf("code to %s", relPath)
 			codeIdx += 1
 
 		case constants.DataSetLayerMediaType:
@@ -92,16 +137,25 @@ func unpackModel(ctx context.Context, store oras.Target, ref *registry.Reference
 			if err != nil {
 				return fmt.Errorf("Error resolving dataset path for dataset %s: %w", datasetEntry.Name, err)
 			}
-			output.Infof("Unpacking dataset %s to %s", datasetEntry.Name, relPath)
+			label = fmt.Sprintf("dataset %s to %s", datasetEntry.Name, relPath)
 			datasetIdx += 1
+
+		default:
+			continue
 		}
-		if err := unpackLayer(ctx, store, layerDesc, layerDir, options.overwrite); err != nil {
-			return err
-		}
+		tasks = append(tasks, layerTask{desc: layerDesc, dir: layerDir, relPath: relPath, label: label})
+	}
+
+	if err := unpackLayers(ctx, store, tasks, options); err != nil {
+		return err
 	}
 	output.Debugf("Unpacked %d code layers", codeIdx)
 	output.Debugf("Unpacked %d dataset layers", datasetIdx)
 
+	if err := unpackReferrers(ctx, store, manifestDesc, options); err != nil {
+		return fmt.Errorf("failed to unpack referrers: %w", err)
+	}
+
 	return nil
 }
 
@@ -127,88 +181,211 @@ func unpackConfig(config *artifact.KitFile, unpackDir string, overwrite bool) er
 	return nil
 }
 
-func unpackLayer(ctx context.Context, store content.Storage, desc ocispec.Descriptor, unpackPath string, overwrite bool) error {
+// unpackLayers fetches and extracts tasks concurrently, bounded by options.concurrency (treated
+// as 1, i.e. sequential, if unset). Progress is tracked in a resume state file so that layers
+// already marked complete from a previous, interrupted run are skipped.
+func unpackLayers(ctx context.Context, store assetsclient.AssetsClient, tasks []layerTask, options *unpackOptions) error {
+	concurrency := options.concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	state := loadUnpackState(unpackStatePath(options.unpackDir))
+
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.SetLimit(concurrency)
+	for _, task := range tasks {
+		task := task
+		eg.Go(func() error {
+			return unpackLayerResumable(egCtx, store, task, options, state)
+		})
+	}
+	return eg.Wait()
+}
+
+// unpackLayerResumable unpacks a single layer, skipping it if the resume state already marks it
+// complete, and recording its resulting state (complete, or partial with the error left for the
+// caller to surface) once done.
+func unpackLayerResumable(ctx context.Context, store assetsclient.AssetsClient, task layerTask, options *unpackOptions, state *unpackState) error {
+	digestKey := task.desc.Digest.String()
+	if st, ok := state.get(digestKey); ok && st.Status == layerStateComplete {
+		output.Debugf("Layer %s already unpacked, skipping", digestKey)
+		return nil
+	}
+
+	output.Infof("Unpacking %s", task.label)
+	written, err := unpackLayer(ctx, store, task.desc, task.dir, options)
+	if err != nil {
+		if stateErr := state.set(digestKey, layerState{Status: layerStatePartial, BytesWritten: written}); stateErr != nil {
+			output.Debugf("Failed to record unpack state for layer %s: %s", digestKey, stateErr)
+		}
+		return err
+	}
+	return state.set(digestKey, layerState{Status: layerStateComplete, BytesWritten: written})
+}
+
+// unpackLayer fetches the blob described by desc, verifying its contents against desc.Digest as
+// they stream in, and extracts it as a gzipped tar archive into unpackPath's parent directory,
+// applying options.includePatterns/options.excludePatterns to each entry. It returns the number
+// of bytes written to disk. If the fetched content does not match desc.Digest, any files already
+// written for this layer are removed before returning an error.
+func unpackLayer(ctx context.Context, store assetsclient.AssetsClient, desc ocispec.Descriptor, unpackPath string, options *unpackOptions) (int64, error) {
 	rc, err := store.Fetch(ctx, desc)
 	if err != nil {
-		return fmt.Errorf("failed get layer %s: %w", desc.Digest, err)
+		return 0, fmt.Errorf("failed get layer %s: %w", desc.Digest, err)
 	}
 	defer rc.Close()
 
-	gzr, err := gzip.NewReader(rc)
+	// desc.Digest.Verifier() only exposes whether the fetched content matched, not what digest
+	// it actually hashed to; a second Digester alongside it lets a mismatch error report both.
+	verifier := desc.Digest.Verifier()
+	digester := desc.Digest.Algorithm().Digester()
+	verifiedReader := io.TeeReader(rc, io.MultiWriter(verifier, digester.Hash()))
+
+	gzr, err := gzip.NewReader(verifiedReader)
 	if err != nil {
-		return fmt.Errorf("error extracting gzipped file: %w", err)
+		return 0, fmt.Errorf("error extracting gzipped file: %w", err)
 	}
 	defer gzr.Close()
 	tr := tar.NewReader(gzr)
 
 	if _, exists := filesystem.PathExists(unpackPath); exists {
-		if !overwrite {
-			return fmt.Errorf("failed to unpack: path %s already exists", unpackPath)
+		if !options.overwrite {
+			return 0, fmt.Errorf("failed to unpack: path %s already exists", unpackPath)
 		}
 		output.Debugf("Directory %s already exists", unpackPath)
 	}
 	unpackDir := filepath.Dir(unpackPath)
 	if err := os.MkdirAll(unpackDir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory %s: %w", unpackDir, err)
+		return 0, fmt.Errorf("failed to create directory %s: %w", unpackDir, err)
 	}
 
-	return extractTar(tr, unpackDir, overwrite)
+	written, extracted, err := extractTar(tr, unpackDir, options)
+	if err == nil {
+		// Drain any bytes not consumed by the tar reader (e.g. the gzip trailer) so the
+		// verifier has seen the entire blob before we check it.
+		_, err = io.Copy(io.Discard, verifiedReader)
+	}
+	if err == nil && !verifier.Verified() {
+		err = fmt.Errorf("layer failed digest verification: expected %s, got %s", desc.Digest, digester.Digest())
+	}
+	if err != nil {
+		removePartialPaths(extracted)
+		return written, err
+	}
+	return written, nil
+}
+
+// extractedPaths tracks the filesystem entries a tar extraction created, so they can be rolled
+// back if the layer turns out to be partial or fails digest verification. files and dirs are
+// recorded in creation order; dirs is walked in reverse on rollback so a child directory is
+// removed before its parent.
+type extractedPaths struct {
+	files []string
+	dirs  []string
+}
+
+// removePartialPaths deletes the files and directories left behind by a layer extraction that
+// failed partway through or failed digest verification, so a subsequent resume attempt starts
+// from a clean slate instead of tripping over leftover paths from the failed attempt.
+func removePartialPaths(extracted extractedPaths) {
+	for _, path := range extracted.files {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			output.Debugf("Failed to remove partial file %s: %s", path, err)
+		}
+	}
+	for i := len(extracted.dirs) - 1; i >= 0; i-- {
+		path := extracted.dirs[i]
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			output.Debugf("Failed to remove partial directory %s: %s", path, err)
+		}
+	}
 }
 
-func extractTar(tr *tar.Reader, dir string, overwrite bool) error {
+// extractTar extracts tr into dir, returning the total number of bytes written and the paths it
+// created (used to roll back a failed or digest-mismatched layer).
+//
+// Each entry's path, relative to the layer root, is matched against options.excludePatterns and
+// options.includePatterns: excluded entries are skipped entirely, and once includePatterns is
+// non-empty only matching entries (plus the ancestor directories needed to hold them) are
+// written. filesystem.VerifySubpath is applied to every entry that survives filtering so a
+// malicious tar cannot escape dir via "../" components.
+func extractTar(tr *tar.Reader, dir string, options *unpackOptions) (int64, extractedPaths, error) {
+	overwrite := options.overwrite
+	var written int64
+	var extracted extractedPaths
 	for {
 		header, err := tr.Next()
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			return err
+			return written, extracted, err
+		}
+		entryPath := filepath.ToSlash(header.Name)
+		if matchAnyGlob(options.excludePatterns, entryPath) {
+			output.Debugf("Skipping excluded entry %s", entryPath)
+			continue
 		}
 		outPath := filepath.Join(dir, header.Name)
+		if _, _, err := filesystem.VerifySubpath(dir, header.Name); err != nil {
+			return written, extracted, fmt.Errorf("invalid entry %s in archive: %w", header.Name, err)
+		}
 
 		switch header.Typeflag {
 		case tar.TypeDir:
+			if len(options.includePatterns) > 0 && !dirMayContainMatch(options.includePatterns, entryPath) {
+				output.Debugf("Skipping directory %s: no included entries beneath it", entryPath)
+				continue
+			}
 			if fi, exists := filesystem.PathExists(outPath); exists {
 				if !overwrite {
-					return fmt.Errorf("path '%s' already exists", outPath)
+					return written, extracted, fmt.Errorf("path '%s' already exists", outPath)
 				}
 				if !fi.IsDir() {
-					return fmt.Errorf("path '%s' already exists and is not a directory", outPath)
+					return written, extracted, fmt.Errorf("path '%s' already exists and is not a directory", outPath)
 				}
 				output.Debugf("Path %s already exists", outPath)
+			} else {
+				extracted.dirs = append(extracted.dirs, outPath)
 			}
 			output.Debugf("Creating directory %s", outPath)
 			if err := os.MkdirAll(outPath, header.FileInfo().Mode()); err != nil {
-				return fmt.Errorf("failed to create directory %s: %w", outPath, err)
+				return written, extracted, fmt.Errorf("failed to create directory %s: %w", outPath, err)
 			}
 
 		case tar.TypeReg:
+			if len(options.includePatterns) > 0 && !matchAnyGlob(options.includePatterns, entryPath) {
+				output.Debugf("Skipping entry %s: does not match include patterns", entryPath)
+				continue
+			}
 			if fi, exists := filesystem.PathExists(outPath); exists {
 				if !overwrite {
-					return fmt.Errorf("path '%s' already exists", outPath)
+					return written, extracted, fmt.Errorf("path '%s' already exists", outPath)
 				}
 				if !fi.Mode().IsRegular() {
-					return fmt.Errorf("path '%s' already exists and is not a regular file", outPath)
+					return written, extracted, fmt.Errorf("path '%s' already exists and is not a regular file", outPath)
 				}
 			}
 			output.Debugf("Extracting file %s", outPath)
 			file, err := os.OpenFile(outPath, os.O_CREATE|os.O_TRUNC|os.O_RDWR, header.FileInfo().Mode())
 			if err != nil {
-				return fmt.Errorf("failed to create file %s: %w", outPath, err)
+				return written, extracted, fmt.Errorf("failed to create file %s: %w", outPath, err)
 			}
+			extracted.files = append(extracted.files, outPath)
 			defer file.Close()
 
-			written, err := io.Copy(file, tr)
+			n, err := io.Copy(file, tr)
 			if err != nil {
-				return fmt.Errorf("failed to write file %s: %w", outPath, err)
+				return written, extracted, fmt.Errorf("failed to write file %s: %w", outPath, err)
 			}
-			if written != header.Size {
-				return fmt.Errorf("could not extract file %s", outPath)
+			written += n
+			if n != header.Size {
+				return written, extracted, fmt.Errorf("could not extract file %s", outPath)
 			}
 
 		default:
-			return fmt.Errorf("Unrecognized type in archive: %s", header.Name)
+			return written, extracted, fmt.Errorf("Unrecognized type in archive: %s", header.Name)
 		}
 	}
-	return nil
+	return written, extracted, nil
 }
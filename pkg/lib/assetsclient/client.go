@@ -0,0 +1,96 @@
+// Copyright 2024 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package assetsclient defines a storage-backend-agnostic client for fetching and pushing
+// ModelKit content. kitops has historically talked to an oras.Target directly, which ties every
+// caller to OCI registries and local OCI layout directories. AssetsClient lets the same pull,
+// unpack, and push code run unmodified against plain HTTP mirrors or object storage, which is a
+// common requirement in air-gapped ML ops environments that don't run a registry.
+package assetsclient
+
+import (
+	"context"
+	"fmt"
+	"kitops/pkg/lib/repo"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/registry"
+)
+
+// AssetsClient is the common interface implemented by every storage backend a ModelKit can be
+// pulled from or pushed to. It embeds content.Storage so that values implementing it can be
+// passed anywhere a content.Storage is expected (e.g. repo.GetManifest, repo.GetConfig).
+type AssetsClient interface {
+	content.Storage
+
+	// Resolve returns the descriptor that ref currently points to.
+	Resolve(ctx context.Context, ref string) (ocispec.Descriptor, error)
+	// ListTags returns the tags known for repository repoName.
+	ListTags(ctx context.Context, repoName string) ([]string, error)
+}
+
+// NewClient constructs the AssetsClient backend appropriate for scheme. opts carries whatever
+// backend-specific addressing and authentication each constructor needs; see NewOCIClient,
+// NewHTTPClient, NewFileClient, and NewS3Client.
+func NewClient(scheme repo.Scheme, opts ClientOptions) (AssetsClient, error) {
+	switch scheme {
+	case repo.SchemeOCI:
+		if opts.Target == nil {
+			return nil, fmt.Errorf("assetsclient: oci backend requires a target store")
+		}
+		return NewOCIClient(opts.Target), nil
+	case repo.SchemeHTTP, repo.SchemeHTTPS:
+		return NewHTTPClient(string(scheme), opts.BaseURL, opts.Credentials)
+	case repo.SchemeFile:
+		return NewFileClient(opts.BaseDir)
+	case repo.SchemeS3:
+		return NewS3Client(opts.BaseURL, opts.Credentials)
+	default:
+		return nil, fmt.Errorf("assetsclient: unrecognized scheme %q", scheme)
+	}
+}
+
+// NewClientForReference constructs the AssetsClient that ref addresses, given the scheme and
+// ref returned by repo.ParseReference for the same reference string.
+//
+// For SchemeOCI, target is wrapped directly: the caller is responsible for resolving the local
+// OCI layout store or remote registry client for ref.Registry/ref.Repository, exactly as kitops
+// always has. For every other scheme, repo.ParseReference has already split the base address
+// out into ref.Registry, which is used as ClientOptions.BaseURL (http(s), s3) or BaseDir (file).
+func NewClientForReference(scheme repo.Scheme, ref *registry.Reference, target oras.Target, creds Credentials) (AssetsClient, error) {
+	opts := ClientOptions{Target: target, Credentials: creds}
+	if scheme != repo.SchemeOCI {
+		opts.BaseURL = ref.Registry
+		opts.BaseDir = ref.Registry
+	}
+	return NewClient(scheme, opts)
+}
+
+// ClientOptions bundles the possible constructor arguments for every backend; only the fields
+// relevant to the requested scheme need to be set.
+type ClientOptions struct {
+	// Target is the oras.Target to wrap for the oci scheme (a remote registry client or a
+	// local OCI layout store).
+	Target oras.Target
+	// BaseURL is the address of an http(s) mirror or s3-compatible endpoint.
+	BaseURL string
+	// BaseDir is the root directory for the file scheme.
+	BaseDir string
+	// Credentials authenticates to the backend, where applicable.
+	Credentials Credentials
+}
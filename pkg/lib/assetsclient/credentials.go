@@ -0,0 +1,36 @@
+// Copyright 2024 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package assetsclient
+
+// Credentials carries the authentication material a non-oci AssetsClient backend may need.
+// Which fields are meaningful depends on the backend: HTTP mirrors use Username/Password,
+// BearerToken, or StaticToken; the S3 backend uses the AWS* fields for SigV4 signing. Backends
+// that don't need authentication (e.g. the file backend) ignore Credentials entirely.
+type Credentials struct {
+	Username string
+	Password string
+
+	BearerToken string
+
+	// StaticToken is a pre-shared token, usually read from a local file, used by internal
+	// HTTP mirrors that don't implement a full OAuth flow.
+	StaticToken string
+
+	AWSAccessKeyID     string
+	AWSSecretAccessKey string
+	AWSSessionToken    string
+}
@@ -0,0 +1,98 @@
+// Copyright 2024 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package assetsclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// fileClient addresses content-addressed blobs stored directly on disk under baseDir (as
+// baseDir/<algorithm>/<hex>), for air-gapped setups that share ModelKit content over a plain
+// mounted filesystem rather than a registry or OCI layout directory.
+type fileClient struct {
+	baseDir string
+}
+
+// NewFileClient returns an AssetsClient that reads and writes blobs under baseDir.
+func NewFileClient(baseDir string) (AssetsClient, error) {
+	if baseDir == "" {
+		return nil, fmt.Errorf("assetsclient: file backend requires a base directory")
+	}
+	return &fileClient{baseDir: baseDir}, nil
+}
+
+func (c *fileClient) blobPath(dgst digest.Digest) string {
+	return filepath.Join(c.baseDir, dgst.Algorithm().String(), dgst.Encoded())
+}
+
+func (c *fileClient) Resolve(ctx context.Context, ref string) (ocispec.Descriptor, error) {
+	dgst, err := digest.Parse(ref)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("file backend references must be a digest, got %q: %w", ref, err)
+	}
+	fi, err := os.Stat(c.blobPath(dgst))
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to resolve %s: %w", ref, err)
+	}
+	return ocispec.Descriptor{Digest: dgst, Size: fi.Size()}, nil
+}
+
+func (c *fileClient) Fetch(ctx context.Context, desc ocispec.Descriptor) (io.ReadCloser, error) {
+	f, err := os.Open(c.blobPath(desc.Digest))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", desc.Digest, err)
+	}
+	return f, nil
+}
+
+func (c *fileClient) Push(ctx context.Context, desc ocispec.Descriptor, rc io.Reader) error {
+	path := c.blobPath(desc.Digest)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", desc.Digest, err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to push %s: %w", desc.Digest, err)
+	}
+	defer f.Close()
+	if _, err := io.Copy(f, rc); err != nil {
+		return fmt.Errorf("failed to write %s: %w", desc.Digest, err)
+	}
+	return nil
+}
+
+func (c *fileClient) Exists(ctx context.Context, desc ocispec.Descriptor) (bool, error) {
+	_, err := os.Stat(c.blobPath(desc.Digest))
+	if err == nil {
+		return true, nil
+	}
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return false, err
+}
+
+func (c *fileClient) ListTags(ctx context.Context, repoName string) ([]string, error) {
+	return nil, fmt.Errorf("assetsclient: file backend does not support listing tags")
+}
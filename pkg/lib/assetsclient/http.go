@@ -0,0 +1,144 @@
+// Copyright 2024 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package assetsclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// httpClient addresses content on a plain HTTP(S) mirror, where a descriptor's digest maps
+// directly onto a URL path beneath baseURL (baseURL/blobs/<digest>), the layout used by
+// internal HTTP-backed ModelKit mirrors that don't run a full registry.
+type httpClient struct {
+	baseURL     string
+	credentials Credentials
+	httpClient  *http.Client
+}
+
+// NewHTTPClient returns an AssetsClient that reads and writes blobs against baseURL over
+// scheme (http or https).
+func NewHTTPClient(scheme, baseURL string, creds Credentials) (AssetsClient, error) {
+	if baseURL == "" {
+		return nil, fmt.Errorf("assetsclient: http backend requires a base URL")
+	}
+	if !strings.HasPrefix(baseURL, scheme+"://") {
+		baseURL = scheme + "://" + baseURL
+	}
+	return &httpClient{baseURL: strings.TrimSuffix(baseURL, "/"), credentials: creds, httpClient: http.DefaultClient}, nil
+}
+
+func (c *httpClient) blobURL(dgst digest.Digest) string {
+	return fmt.Sprintf("%s/blobs/%s", c.baseURL, dgst)
+}
+
+func (c *httpClient) authenticate(req *http.Request) {
+	switch {
+	case c.credentials.BearerToken != "":
+		req.Header.Set("Authorization", "Bearer "+c.credentials.BearerToken)
+	case c.credentials.StaticToken != "":
+		req.Header.Set("Authorization", "Bearer "+c.credentials.StaticToken)
+	case c.credentials.Username != "":
+		req.SetBasicAuth(c.credentials.Username, c.credentials.Password)
+	}
+}
+
+func (c *httpClient) Resolve(ctx context.Context, ref string) (ocispec.Descriptor, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, fmt.Sprintf("%s/manifests/%s", c.baseURL, ref), nil)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	c.authenticate(req)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to resolve %s: %w", ref, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to resolve %s: server returned %s", ref, resp.Status)
+	}
+	dgst, err := digest.Parse(resp.Header.Get("Docker-Content-Digest"))
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to resolve %s: missing or invalid digest header: %w", ref, err)
+	}
+	size, _ := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+	return ocispec.Descriptor{
+		MediaType: resp.Header.Get("Content-Type"),
+		Digest:    dgst,
+		Size:      size,
+	}, nil
+}
+
+func (c *httpClient) Fetch(ctx context.Context, desc ocispec.Descriptor) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.blobURL(desc.Digest), nil)
+	if err != nil {
+		return nil, err
+	}
+	c.authenticate(req)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", desc.Digest, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to fetch %s: server returned %s", desc.Digest, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+func (c *httpClient) Push(ctx context.Context, desc ocispec.Descriptor, rc io.Reader) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, c.blobURL(desc.Digest), rc)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = desc.Size
+	c.authenticate(req)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to push %s: %w", desc.Digest, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("failed to push %s: server returned %s", desc.Digest, resp.Status)
+	}
+	return nil
+}
+
+func (c *httpClient) Exists(ctx context.Context, desc ocispec.Descriptor) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, c.blobURL(desc.Digest), nil)
+	if err != nil {
+		return false, err
+	}
+	c.authenticate(req)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to check %s: %w", desc.Digest, err)
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode == http.StatusOK, nil
+}
+
+func (c *httpClient) ListTags(ctx context.Context, repoName string) ([]string, error) {
+	return nil, fmt.Errorf("assetsclient: http backend does not support listing tags")
+}
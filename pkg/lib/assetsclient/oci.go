@@ -0,0 +1,94 @@
+// Copyright 2024 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package assetsclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"kitops/pkg/lib/repo"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+)
+
+// ociClient adapts an oras.Target -- a remote registry client or a local OCI layout store, the
+// two backends kitops has always supported -- to the AssetsClient interface.
+type ociClient struct {
+	target oras.Target
+}
+
+// NewOCIClient returns an AssetsClient backed by target.
+func NewOCIClient(target oras.Target) AssetsClient {
+	return &ociClient{target: target}
+}
+
+func (c *ociClient) Resolve(ctx context.Context, ref string) (ocispec.Descriptor, error) {
+	return c.target.Resolve(ctx, ref)
+}
+
+func (c *ociClient) Fetch(ctx context.Context, desc ocispec.Descriptor) (io.ReadCloser, error) {
+	return c.target.Fetch(ctx, desc)
+}
+
+func (c *ociClient) Push(ctx context.Context, desc ocispec.Descriptor, rc io.Reader) error {
+	return c.target.Push(ctx, desc, rc)
+}
+
+func (c *ociClient) Exists(ctx context.Context, desc ocispec.Descriptor) (bool, error) {
+	return c.target.Exists(ctx, desc)
+}
+
+func (c *ociClient) ListTags(ctx context.Context, repoName string) ([]string, error) {
+	lister, ok := c.target.(interface {
+		Tags(ctx context.Context, last string, fn func(tags []string) error) error
+	})
+	if !ok {
+		return nil, fmt.Errorf("assetsclient: oci target for %s does not support listing tags", repoName)
+	}
+	var tags []string
+	if err := lister.Tags(ctx, "", func(t []string) error {
+		tags = append(tags, t...)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to list tags for %s: %w", repoName, err)
+	}
+	return tags, nil
+}
+
+// Referrers passes through to the underlying oras.Target's referrers API, if it implements
+// one, so that repo.ListReferrers keeps working when called with an ociClient. For targets
+// without a referrers API (e.g. plain OCI layout stores predating OCI 1.1), repo.ListReferrers
+// is called on the target itself, which scans its local index; targets that have neither
+// return an error.
+func (c *ociClient) Referrers(ctx context.Context, desc ocispec.Descriptor, artifactType string, fn func([]ocispec.Descriptor) error) error {
+	lister, ok := c.target.(interface {
+		Referrers(ctx context.Context, desc ocispec.Descriptor, artifactType string, fn func([]ocispec.Descriptor) error) error
+	})
+	if !ok {
+		var artifactTypes []string
+		if artifactType != "" {
+			artifactTypes = []string{artifactType}
+		}
+		referrers, err := repo.ListReferrers(ctx, c.target, desc, artifactTypes)
+		if err != nil {
+			return fmt.Errorf("assetsclient: oci target does not support the referrers API: %w", err)
+		}
+		return fn(referrers)
+	}
+	return lister.Referrers(ctx, desc, artifactType, fn)
+}
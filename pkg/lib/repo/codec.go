@@ -0,0 +1,96 @@
+// Copyright 2024 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package repo
+
+import (
+	"encoding/json"
+	"fmt"
+	"kitops/pkg/artifact"
+	"kitops/pkg/lib/constants"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ModelConfigMediaTypeV1Alpha is a placeholder for a pre-stabilization Kitfile schema,
+// registered alongside the current v1 schema to demonstrate that more than one schema version
+// can be served at once. It decodes identically to v1 today; a real schema change should
+// register its own media type and decode function rather than overloading this one.
+const ModelConfigMediaTypeV1Alpha = "application/vnd.kitops.modelkit.config.v1alpha+json"
+
+// ConfigCodec decodes a Kitfile config blob into the common *artifact.KitFile representation
+// used throughout kitops, for one specific config media type.
+type ConfigCodec func(configBytes []byte) (*artifact.KitFile, error)
+
+var (
+	codecMu sync.RWMutex
+	codecs  = map[string]ConfigCodec{}
+)
+
+func init() {
+	RegisterConfigCodec(constants.ModelConfigMediaType, decodeV1Config)
+	RegisterConfigCodec(ModelConfigMediaTypeV1Alpha, decodeV1Config)
+}
+
+// RegisterConfigCodec associates mediaType with decode, so that GetConfig (and, transitively,
+// GetManifest) can recognize and parse Kitfile config blobs of that media type. This lets the
+// Kitfile schema evolve -- new fields for datasets, prompts, evals, adapters -- across
+// versioned media types without breaking clients still producing or consuming an older schema,
+// mirroring how container registries register a handler per manifest schema (schema1, schema2,
+// the OCI image schema) instead of hard-coding a single format.
+//
+// RegisterConfigCodec is typically called from an init function; it is safe for concurrent use.
+func RegisterConfigCodec(mediaType string, decode ConfigCodec) {
+	codecMu.Lock()
+	defer codecMu.Unlock()
+	codecs[mediaType] = decode
+}
+
+// IsRegisteredConfigMediaType returns true if mediaType has a codec registered via
+// RegisterConfigCodec.
+func IsRegisteredConfigMediaType(mediaType string) bool {
+	codecMu.RLock()
+	defer codecMu.RUnlock()
+	_, ok := codecs[mediaType]
+	return ok
+}
+
+// configCodec returns the registered ConfigCodec for mediaType, or an error listing the
+// registered schemas if none is registered.
+func configCodec(mediaType string) (ConfigCodec, error) {
+	codecMu.RLock()
+	defer codecMu.RUnlock()
+	decode, ok := codecs[mediaType]
+	if !ok {
+		registered := make([]string, 0, len(codecs))
+		for mt := range codecs {
+			registered = append(registered, mt)
+		}
+		sort.Strings(registered)
+		return nil, fmt.Errorf("unsupported Kitfile schema %q; registered schemas: %s", mediaType, strings.Join(registered, ", "))
+	}
+	return decode, nil
+}
+
+// decodeV1Config decodes the current (v1) Kitfile config media type.
+func decodeV1Config(configBytes []byte) (*artifact.KitFile, error) {
+	config := &artifact.KitFile{}
+	if err := json.Unmarshal(configBytes, config); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	return config, nil
+}
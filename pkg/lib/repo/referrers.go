@@ -0,0 +1,158 @@
+// Copyright 2024 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package repo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"kitops/pkg/output"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+)
+
+// Media types for artifacts that can be attached to a ModelKit manifest via the OCI 1.1
+// referrers API, keyed by subject rather than bundled into the ModelKit itself.
+const (
+	SignatureArtifactType  = "application/vnd.kitops.signature.v1+json"
+	SBOMArtifactType       = "application/vnd.kitops.sbom.v1+json"
+	EvalReportArtifactType = "application/vnd.kitops.eval.v1+json"
+)
+
+// ListReferrers returns the descriptors of all manifests whose subject is subject and whose
+// artifactType matches one of artifactTypes (all types are returned if artifactTypes is empty).
+// If store has a Referrers method (as registry.ReferrerLister does) and it succeeds, the
+// registry's referrers API is used; otherwise the
+// local index is scanned for manifests whose subject descriptor matches subject, which is the
+// fallback used for local OCI layout stores that predate OCI 1.1 referrers support.
+func ListReferrers(ctx context.Context, store content.Storage, subject ocispec.Descriptor, artifactTypes []string) ([]ocispec.Descriptor, error) {
+	wantType := func(artifactType string) bool {
+		if len(artifactTypes) == 0 {
+			return true
+		}
+		for _, want := range artifactTypes {
+			if want == artifactType {
+				return true
+			}
+		}
+		return false
+	}
+
+	if lister, ok := store.(interface {
+		Referrers(ctx context.Context, desc ocispec.Descriptor, artifactType string, fn func([]ocispec.Descriptor) error) error
+	}); ok {
+		var referrers []ocispec.Descriptor
+		err := lister.Referrers(ctx, subject, "", func(descs []ocispec.Descriptor) error {
+			for _, desc := range descs {
+				if wantType(desc.ArtifactType) {
+					referrers = append(referrers, desc)
+				}
+			}
+			return nil
+		})
+		if err == nil {
+			return referrers, nil
+		}
+		output.Debugf("Referrers API unavailable for %s (%s), falling back to local index scan", subject.Digest, err)
+	}
+
+	// Fall back to scanning the local index for manifests with a matching subject.
+	index, ok := store.(LocalStorage)
+	if !ok {
+		return nil, fmt.Errorf("store does not support the referrers API and is not a local index")
+	}
+	idx, err := index.GetIndex()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read local index: %w", err)
+	}
+	var referrers []ocispec.Descriptor
+	for _, desc := range idx.Manifests {
+		// Use GetRawManifest, not GetManifest: index entries include referrer manifests
+		// (pushed by AttachArtifact with a non-Kitfile Config), which GetManifest would
+		// reject as "not a model" before Subject is ever inspected.
+		manifest, err := GetRawManifest(ctx, store, desc)
+		if err != nil {
+			// Not every entry in the index is necessarily a manifest kitops understands
+			// at all; skip anything we can't parse.
+			continue
+		}
+		if manifest.Subject == nil || manifest.Subject.Digest != subject.Digest {
+			continue
+		}
+		if wantType(manifest.ArtifactType) {
+			referrers = append(referrers, desc)
+		}
+	}
+	return referrers, nil
+}
+
+// AttachArtifact pushes a new manifest of the given artifactType into store, with its subject
+// set to subjectDesc, and its single layer populated from content. This is used to attach
+// supply-chain metadata (signatures, SBOMs, eval reports) to a ModelKit digest without
+// modifying the ModelKit's own manifest.
+func AttachArtifact(ctx context.Context, store oras.Target, subjectDesc ocispec.Descriptor, artifactType string, content io.Reader, contentMediaType string) (ocispec.Descriptor, error) {
+	contentBytes, err := io.ReadAll(content)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to read referrer content: %w", err)
+	}
+	layerDesc, err := pushBlob(ctx, store, contentMediaType, contentBytes)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to push referrer content: %w", err)
+	}
+
+	manifest := ocispec.Manifest{
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: artifactType,
+		Config:       ocispec.DescriptorEmptyJSON,
+		Layers:       []ocispec.Descriptor{layerDesc},
+		Subject:      &subjectDesc,
+	}
+	manifestBytes, err := json.Marshal(manifest)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to marshal referrer manifest: %w", err)
+	}
+	manifestDesc, err := pushBlob(ctx, store, ocispec.MediaTypeImageManifest, manifestBytes)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to push referrer manifest: %w", err)
+	}
+	manifestDesc.ArtifactType = artifactType
+	// Tags can't contain ':', so fall back to the same digest-as-tag scheme used elsewhere
+	// for referencing content by digest (e.g. "sha256:abcd..." -> "sha256-abcd...").
+	tag := strings.ReplaceAll(manifestDesc.Digest.String(), ":", "-")
+	if err := store.Tag(ctx, manifestDesc, tag); err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to tag referrer manifest: %w", err)
+	}
+	return manifestDesc, nil
+}
+
+func pushBlob(ctx context.Context, store content.Pusher, mediaType string, data []byte) (ocispec.Descriptor, error) {
+	desc := ocispec.Descriptor{
+		MediaType: mediaType,
+		Digest:    digest.FromBytes(data),
+		Size:      int64(len(data)),
+	}
+	if err := store.Push(ctx, desc, bytes.NewReader(data)); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	return desc, nil
+}
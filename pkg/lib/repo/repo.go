@@ -21,7 +21,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"kitops/pkg/artifact"
-	"kitops/pkg/lib/constants"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -42,6 +41,22 @@ var (
 	validTagRegex = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9._-]{0,127}$`)
 )
 
+// Scheme identifies which AssetsClient backend a reference addresses.
+type Scheme string
+
+const (
+	// SchemeOCI is the default backend: an OCI registry or local OCI layout store, addressed
+	// the way kitops always has (registry/repository:tag).
+	SchemeOCI Scheme = "oci"
+	// SchemeHTTP and SchemeHTTPS address a plain HTTP(S) ModelKit mirror.
+	SchemeHTTP  Scheme = "http"
+	SchemeHTTPS Scheme = "https"
+	// SchemeS3 addresses an S3 (or S3-compatible, e.g. GCS) bucket.
+	SchemeS3 Scheme = "s3"
+	// SchemeFile addresses content-addressed blobs stored directly on a local filesystem.
+	SchemeFile Scheme = "file"
+)
+
 // ParseReference parses a reference string into a Reference struct. It attempts to make
 // references conform to an expected structure, with a defined registry and repository by filling
 // default values for registry and repository where appropriate. Where the first part of a reference
@@ -49,9 +64,28 @@ var (
 // localhost/testorg/testrepo. If refString does not contain a registry or a repository (i.e. is a
 // base SHA256 hash), the returned reference uses placeholder values for registry and repository.
 //
+// refString may be prefixed with a URI scheme (oci://, http://, https://, s3://, file://)
+// identifying which AssetsClient backend it addresses; the scheme is returned separately and
+// stripped before the rest of refString is parsed. A reference without a recognized scheme is
+// treated as SchemeOCI, preserving today's behavior for bare org/repo references.
+//
+// For non-oci schemes, the remainder of refString is split on its final "/" into a base address
+// and a specific reference, reusing the Reference struct's Registry and Reference fields to carry
+// them: Registry becomes the backend's base address (a URL for http(s)/s3, a directory for
+// file) to pass as assetsclient.ClientOptions.BaseURL/BaseDir, and Reference becomes the digest
+// or tag passed to AssetsClient.Resolve. For example "http://mirror.example.com/models/sha256:abcd"
+// yields Registry "mirror.example.com/models" and Reference "sha256:abcd"; a refString with no
+// "/" after the scheme (a bare digest or tag) yields an empty Registry.
+//
 // See FormatRepositoryForDisplay for removing default values from a registry for displaying to the
 // user.
-func ParseReference(refString string) (ref *registry.Reference, extraTags []string, err error) {
+func ParseReference(refString string) (scheme Scheme, ref *registry.Reference, extraTags []string, err error) {
+	scheme, refString = splitScheme(refString)
+	if scheme != SchemeOCI {
+		base, ref := splitBaseAndReference(refString)
+		return scheme, &registry.Reference{Registry: base, Reference: ref}, nil, nil
+	}
+
 	// Check if provided input is a plain digest
 	if _, err := digest.Parse(refString); err == nil {
 		ref := &registry.Reference{
@@ -59,7 +93,7 @@ func ParseReference(refString string) (ref *registry.Reference, extraTags []stri
 			Repository: DefaultRepository,
 			Reference:  refString,
 		}
-		return ref, []string{}, nil
+		return scheme, ref, []string{}, nil
 	}
 
 	// Handle registry, which may or may not be specified; if unspecified, use a default value for registry
@@ -82,9 +116,34 @@ func ParseReference(refString string) (ref *registry.Reference, extraTags []stri
 	refAndTags := strings.Split(refString, ",")
 	baseRef, err := registry.ParseReference(refAndTags[0])
 	if err != nil {
-		return nil, nil, err
+		return scheme, nil, nil, err
+	}
+	return scheme, &baseRef, refAndTags[1:], nil
+}
+
+// splitScheme strips a recognized URI scheme prefix (e.g. "s3://") from refString, returning the
+// identified Scheme and the remainder of refString. References without a recognized prefix are
+// treated as SchemeOCI and returned unchanged.
+func splitScheme(refString string) (Scheme, string) {
+	for _, scheme := range []Scheme{SchemeOCI, SchemeHTTPS, SchemeHTTP, SchemeS3, SchemeFile} {
+		prefix := string(scheme) + "://"
+		if strings.HasPrefix(refString, prefix) {
+			return scheme, strings.TrimPrefix(refString, prefix)
+		}
+	}
+	return SchemeOCI, refString
+}
+
+// splitBaseAndReference splits a non-oci backend reference (the part of refString remaining
+// after splitScheme) into a base address and a specific reference beneath it, at the final "/".
+// A reference with no "/" (just a bare digest or tag) has an empty base, addressing the
+// backend's root directly.
+func splitBaseAndReference(refString string) (base, ref string) {
+	idx := strings.LastIndex(refString, "/")
+	if idx < 0 {
+		return "", refString
 	}
-	return &baseRef, refAndTags[1:], nil
+	return refString[:idx], refString[idx+1:]
 }
 
 // DefaultReference returns a reference that can be used when no reference is supplied. It uses
@@ -127,6 +186,23 @@ func GetManifestAndConfig(ctx context.Context, store content.Storage, manifestDe
 // GetManifest returns the Manifest described by a Descriptor. Returns an error if the manifest blob cannot be
 // resolved or does not represent a modelkit manifest.
 func GetManifest(ctx context.Context, store content.Storage, manifestDesc ocispec.Descriptor) (*ocispec.Manifest, error) {
+	manifest, err := GetRawManifest(ctx, store, manifestDesc)
+	if err != nil {
+		return nil, err
+	}
+	if !IsRegisteredConfigMediaType(manifest.Config.MediaType) {
+		return nil, fmt.Errorf("reference exists but is not a model")
+	}
+
+	return manifest, nil
+}
+
+// GetRawManifest returns the Manifest described by a Descriptor, without checking that its
+// Config.MediaType is a registered Kitfile schema. Most callers want GetManifest, which adds
+// that check to reject non-ModelKit content; GetRawManifest is for manifests that were never
+// going to have a Kitfile config in the first place, such as OCI 1.1 referrer manifests attached
+// via AttachArtifact (whose Config is ocispec.DescriptorEmptyJSON).
+func GetRawManifest(ctx context.Context, store content.Storage, manifestDesc ocispec.Descriptor) (*ocispec.Manifest, error) {
 	manifestBytes, err := content.FetchAll(ctx, store, manifestDesc)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read manifest %s: %w", manifestDesc.Digest, err)
@@ -135,28 +211,22 @@ func GetManifest(ctx context.Context, store content.Storage, manifestDesc ocispe
 	if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
 		return nil, fmt.Errorf("failed to parse manifest %s: %w", manifestDesc.Digest, err)
 	}
-	if manifest.Config.MediaType != constants.ModelConfigMediaType {
-		return nil, fmt.Errorf("reference exists but is not a model")
-	}
-
 	return manifest, nil
 }
 
 // GetConfig returns the config (Kitfile) described by a descriptor. Returns an error if the config blob cannot
-// be resolved or if the descriptor does not describe a Kitfile.
+// be resolved, or if the descriptor's media type does not have a codec registered via
+// RegisterConfigCodec.
 func GetConfig(ctx context.Context, store content.Storage, configDesc ocispec.Descriptor) (*artifact.KitFile, error) {
-	if configDesc.MediaType != constants.ModelConfigMediaType {
-		return nil, fmt.Errorf("configuration descriptor does not describe a Kitfile")
+	decode, err := configCodec(configDesc.MediaType)
+	if err != nil {
+		return nil, err
 	}
 	configBytes, err := content.FetchAll(ctx, store, configDesc)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config: %w", err)
 	}
-	config := &artifact.KitFile{}
-	if err := json.Unmarshal(configBytes, config); err != nil {
-		return nil, fmt.Errorf("failed to parse config: %w", err)
-	}
-	return config, nil
+	return decode(configBytes)
 }
 
 // ResolveManifest returns the manifest for a reference (tag), if present in the target store
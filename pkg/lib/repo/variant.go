@@ -0,0 +1,116 @@
+// Copyright 2024 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+)
+
+// Annotation keys used on the child manifests of an OCI image index to describe the variant
+// of a ModelKit each child represents, e.g. a quantization, framework, or hardware target.
+const (
+	AnnotationVariantQuantization = "kitops.variant.quantization"
+	AnnotationVariantFramework    = "kitops.variant.framework"
+	AnnotationVariantAccelerator  = "kitops.variant.accelerator"
+)
+
+// VariantSelector describes which child manifest of a multi-variant ModelKit (expressed as
+// an OCI image index) should be resolved. A non-empty Digest selects a child manifest
+// directly; otherwise Requirements is matched against the kitops.variant.* annotations of
+// each child descriptor, and every non-empty requirement must match for a descriptor to be
+// considered a match.
+type VariantSelector struct {
+	Digest       string
+	Requirements map[string]string
+}
+
+// IsEmpty returns true if the selector carries neither a digest nor any requirements, meaning
+// any single variant is acceptable.
+func (s *VariantSelector) IsEmpty() bool {
+	return s == nil || (s.Digest == "" && len(s.Requirements) == 0)
+}
+
+// matches returns true if desc satisfies every constraint in the selector.
+func (s *VariantSelector) matches(desc ocispec.Descriptor) bool {
+	if s.Digest != "" {
+		return desc.Digest.String() == s.Digest
+	}
+	for key, want := range s.Requirements {
+		if want == "" {
+			continue
+		}
+		if desc.Annotations[key] != want {
+			return false
+		}
+	}
+	return true
+}
+
+// GetIndex returns the Index described by a Descriptor. Returns an error if the index blob
+// cannot be resolved or does not represent an OCI image index.
+func GetIndex(ctx context.Context, store content.Storage, indexDesc ocispec.Descriptor) (*ocispec.Index, error) {
+	indexBytes, err := content.FetchAll(ctx, store, indexDesc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index %s: %w", indexDesc.Digest, err)
+	}
+	index := &ocispec.Index{}
+	if err := json.Unmarshal(indexBytes, index); err != nil {
+		return nil, fmt.Errorf("failed to parse index %s: %w", indexDesc.Digest, err)
+	}
+	if index.MediaType != ocispec.MediaTypeImageIndex {
+		return nil, fmt.Errorf("%s is not an OCI image index (media type %q)", indexDesc.Digest, index.MediaType)
+	}
+	return index, nil
+}
+
+// ResolveVariant resolves manifestDesc to the single ModelKit manifest it should be treated
+// as. If manifestDesc describes an OCI image index (a ModelKit with multiple variants), its
+// child manifests are filtered using selector and the one matching descriptor is returned; it
+// is an error for zero or more than one child to match. If manifestDesc already describes a
+// single manifest, it is returned unchanged and selector is ignored.
+//
+// Callers such as list/inspect/pull that want to enumerate variants rather than pick one
+// should call GetIndex directly instead.
+func ResolveVariant(ctx context.Context, store content.Storage, manifestDesc ocispec.Descriptor, selector *VariantSelector) (ocispec.Descriptor, error) {
+	if manifestDesc.MediaType != ocispec.MediaTypeImageIndex {
+		return manifestDesc, nil
+	}
+	index, err := GetIndex(ctx, store, manifestDesc)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	var matches []ocispec.Descriptor
+	for _, childDesc := range index.Manifests {
+		if selector.IsEmpty() || selector.matches(childDesc) {
+			matches = append(matches, childDesc)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return ocispec.Descriptor{}, fmt.Errorf("no variant in index %s matches the requested selector", manifestDesc.Digest)
+	case 1:
+		return matches[0], nil
+	default:
+		return ocispec.Descriptor{}, fmt.Errorf("selector matches %d variants in index %s; specify more variant fields or a digest to disambiguate", len(matches), manifestDesc.Digest)
+	}
+}
@@ -0,0 +1,216 @@
+// Copyright 2024 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package repo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"kitops/pkg/lib/constants"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/oci"
+)
+
+func TestVariantSelectorMatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector *VariantSelector
+		desc     ocispec.Descriptor
+		want     bool
+	}{
+		{
+			name:     "digest match",
+			selector: &VariantSelector{Digest: "sha256:aaa"},
+			desc:     ocispec.Descriptor{Digest: digest.Digest("sha256:aaa")},
+			want:     true,
+		},
+		{
+			name:     "digest mismatch ignores requirements",
+			selector: &VariantSelector{Digest: "sha256:aaa", Requirements: map[string]string{AnnotationVariantQuantization: "int4"}},
+			desc: ocispec.Descriptor{
+				Digest:      digest.Digest("sha256:bbb"),
+				Annotations: map[string]string{AnnotationVariantQuantization: "int4"},
+			},
+			want: false,
+		},
+		{
+			name:     "single requirement match",
+			selector: &VariantSelector{Requirements: map[string]string{AnnotationVariantQuantization: "int4"}},
+			desc:     ocispec.Descriptor{Annotations: map[string]string{AnnotationVariantQuantization: "int4"}},
+			want:     true,
+		},
+		{
+			name: "all requirements must match",
+			selector: &VariantSelector{Requirements: map[string]string{
+				AnnotationVariantQuantization: "int4",
+				AnnotationVariantAccelerator:  "cuda",
+			}},
+			desc: ocispec.Descriptor{Annotations: map[string]string{
+				AnnotationVariantQuantization: "int4",
+				AnnotationVariantAccelerator:  "cpu",
+			}},
+			want: false,
+		},
+		{
+			name:     "empty selector matches anything",
+			selector: &VariantSelector{},
+			desc:     ocispec.Descriptor{Annotations: map[string]string{AnnotationVariantQuantization: "int4"}},
+			want:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.selector.matches(tt.desc); got != tt.want {
+				t.Errorf("matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVariantSelectorIsEmpty(t *testing.T) {
+	var nilSelector *VariantSelector
+	if !nilSelector.IsEmpty() {
+		t.Error("nil selector should be empty")
+	}
+	if !(&VariantSelector{}).IsEmpty() {
+		t.Error("zero-value selector should be empty")
+	}
+	if (&VariantSelector{Digest: "sha256:aaa"}).IsEmpty() {
+		t.Error("selector with a digest should not be empty")
+	}
+	if (&VariantSelector{Requirements: map[string]string{"k": "v"}}).IsEmpty() {
+		t.Error("selector with requirements should not be empty")
+	}
+}
+
+// pushJSON marshals v, pushes it into store under mediaType, and returns its descriptor.
+func pushJSON(t *testing.T, store oras.Target, mediaType string, v any, annotations map[string]string) ocispec.Descriptor {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("failed to marshal blob: %v", err)
+	}
+	desc := ocispec.Descriptor{
+		MediaType:   mediaType,
+		Digest:      digest.FromBytes(data),
+		Size:        int64(len(data)),
+		Annotations: annotations,
+	}
+	if err := store.Push(context.Background(), desc, bytes.NewReader(data)); err != nil {
+		t.Fatalf("failed to push blob: %v", err)
+	}
+	return desc
+}
+
+// TestResolveVariantRoundTrip pushes a ModelKit image index with three variant manifests into a
+// local OCI store and checks that ResolveVariant picks the right one (or errors) for a range of
+// selectors, round-tripping the index through GetIndex along the way.
+func TestResolveVariantRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store, err := oci.New(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create local OCI store: %v", err)
+	}
+
+	configDesc := pushJSON(t, store, constants.ModelConfigMediaType, map[string]string{}, nil)
+
+	newVariant := func(quant, accel string) ocispec.Descriptor {
+		annotations := map[string]string{
+			AnnotationVariantQuantization: quant,
+			AnnotationVariantAccelerator:  accel,
+		}
+		// The annotations are also written into the manifest itself so that every variant has
+		// distinct content, and therefore a distinct digest.
+		manifest := ocispec.Manifest{MediaType: ocispec.MediaTypeImageManifest, Config: configDesc, Annotations: annotations}
+		return pushJSON(t, store, ocispec.MediaTypeImageManifest, manifest, annotations)
+	}
+	int4Cuda := newVariant("int4", "cuda")
+	int4Cpu := newVariant("int4", "cpu")
+	fp16Cuda := newVariant("fp16", "cuda")
+
+	index := ocispec.Index{
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: []ocispec.Descriptor{int4Cuda, int4Cpu, fp16Cuda},
+	}
+	indexDesc := pushJSON(t, store, ocispec.MediaTypeImageIndex, index, nil)
+
+	gotIndex, err := GetIndex(ctx, store, indexDesc)
+	if err != nil {
+		t.Fatalf("GetIndex() returned error: %v", err)
+	}
+	if len(gotIndex.Manifests) != 3 {
+		t.Fatalf("GetIndex() returned %d manifests, want 3", len(gotIndex.Manifests))
+	}
+
+	tests := []struct {
+		name     string
+		selector *VariantSelector
+		want     digest.Digest
+		wantErr  bool
+	}{
+		{
+			name:     "digest selects exact variant",
+			selector: &VariantSelector{Digest: int4Cpu.Digest.String()},
+			want:     int4Cpu.Digest,
+		},
+		{
+			name:     "requirements narrow to a single match",
+			selector: &VariantSelector{Requirements: map[string]string{AnnotationVariantQuantization: "fp16"}},
+			want:     fp16Cuda.Digest,
+		},
+		{
+			name:     "requirements matching nothing is an error",
+			selector: &VariantSelector{Requirements: map[string]string{AnnotationVariantQuantization: "int8"}},
+			wantErr:  true,
+		},
+		{
+			name:     "requirements matching more than one variant is an error",
+			selector: &VariantSelector{Requirements: map[string]string{AnnotationVariantQuantization: "int4"}},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveVariant(ctx, store, indexDesc, tt.selector)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Digest != tt.want {
+				t.Errorf("ResolveVariant() selected %s, want %s", got.Digest, tt.want)
+			}
+		})
+	}
+
+	// A descriptor that isn't an index should be returned unchanged, regardless of selector.
+	single, err := ResolveVariant(ctx, store, int4Cuda, &VariantSelector{Digest: "sha256:doesnotmatter"})
+	if err != nil {
+		t.Fatalf("unexpected error resolving a non-index manifest: %v", err)
+	}
+	if single.Digest != int4Cuda.Digest {
+		t.Errorf("ResolveVariant() on a single manifest returned %s, want %s", single.Digest, int4Cuda.Digest)
+	}
+}